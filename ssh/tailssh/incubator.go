@@ -133,9 +133,7 @@ func (ss *sshSession) newIncubatorCommand() (cmd *exec.Cmd) {
 			shouldUseLoginCmd = false
 		}
 		if shouldUseLoginCmd {
-			if lp, err := exec.LookPath("login"); err == nil {
-				incubatorArgs = append(incubatorArgs, "--login-cmd="+lp)
-			}
+			incubatorArgs = append(incubatorArgs, loginCmdArgs(ss.logf, runtime.GOOS)...)
 		}
 		incubatorArgs = append(incubatorArgs, "--cmd="+name)
 		if len(args) > 0 {
@@ -146,6 +144,44 @@ func (ss *sshSession) newIncubatorCommand() (cmd *exec.Cmd) {
 	return exec.CommandContext(ss.ctx, ss.conn.srv.tailscaledPath, incubatorArgs...)
 }
 
+// loginCmdArgs returns the incubator flags that tell it which login binary
+// to exec into, and on darwin which flags to pass to it. It returns nil if
+// no login binary could be found.
+func loginCmdArgs(logf logger.Logf, goos string) []string {
+	lp := loginCmdPath(logf, goos)
+	if lp == "" {
+		return nil
+	}
+	args := []string{"--login-cmd=" + lp}
+	if f := sshLoginFlags(); f != "" && goos == "darwin" {
+		args = append(args, "--login-flags="+f)
+	}
+	return args
+}
+
+// loginCmdPath returns the path of the login binary to exec into from the
+// incubator, or the empty string if none could be found.
+//
+// On darwin, TS_SSH_LOGIN_CMD overrides the default of looking up "login"
+// in $PATH. The override must be the absolute path of an executable; if it
+// isn't, it is logged and ignored.
+func loginCmdPath(logf logger.Logf, goos string) string {
+	if p := sshLoginCmd(); p != "" && goos == "darwin" {
+		if !filepath.IsAbs(p) {
+			logf("ignoring TS_SSH_LOGIN_CMD=%q: not an absolute path", p)
+		} else if lp, err := exec.LookPath(p); err != nil {
+			logf("ignoring TS_SSH_LOGIN_CMD=%q: %v", p, err)
+		} else {
+			return lp
+		}
+	}
+	lp, err := exec.LookPath("login")
+	if err != nil {
+		return ""
+	}
+	return lp
+}
+
 const debugIncubator = false
 
 type stdRWC struct{}
@@ -177,6 +213,17 @@ type incubatorArgs struct {
 	isShell      bool
 	loginCmdPath string
 	cmdArgs      []string
+
+	// loginFlags, if non-empty, replaces the default flags passed to the
+	// login binary on macOS. See darwinLoginArgs.
+	loginFlags []string
+
+	// loginEnv is the list of KEY=VALUE environment variables that are
+	// explicitly exported into the session started by the login binary on
+	// macOS if loginFlags doesn't preserve the environment. It is populated
+	// by loginArgs from the incubator's own environment and is not a flag.
+	// See darwinLoginEnv.
+	loginEnv []string
 }
 
 func parseIncubatorArgs(args []string) (a incubatorArgs) {
@@ -193,7 +240,9 @@ func parseIncubatorArgs(args []string) (a incubatorArgs) {
 	flags.BoolVar(&a.isShell, "shell", false, "is launching a shell (with no cmds)")
 	flags.BoolVar(&a.isSFTP, "sftp", false, "run sftp server (cmd is ignored)")
 	flags.StringVar(&a.loginCmdPath, "login-cmd", "", "the path to `login` cmd")
+	loginFlags := flags.String("login-flags", "", "flags to pass to the `login` cmd instead of -f -p (darwin only); split on whitespace, with no quoting")
 	flags.Parse(args)
+	a.loginFlags = strings.Fields(*loginFlags)
 	a.cmdArgs = flags.Args()
 	return a
 }
@@ -236,7 +285,7 @@ func beIncubator(args []string) error {
 	if runningAsRoot && ia.loginCmdPath != "" {
 		// Check if we can exec into the login command instead of trying to
 		// incubate ourselves.
-		if la := ia.loginArgs(); la != nil {
+		if la := ia.loginArgs(runtime.GOOS, os.Environ()); la != nil {
 			return unix.Exec(ia.loginCmdPath, la, os.Environ())
 		}
 	}
@@ -735,31 +784,19 @@ func fileExists(path string) bool {
 // The login binary is only used:
 //   - on darwin, if the client is requesting a shell or a command.
 //   - on linux and BSD, if the client is requesting a shell with a TTY.
-func (ia *incubatorArgs) loginArgs() []string {
+//
+// goos is the value of runtime.GOOS and environ is the incubator's
+// environment, passed in for tests.
+func (ia *incubatorArgs) loginArgs(goos string, environ []string) []string {
 	if ia.isSFTP {
 		return nil
 	}
-	switch runtime.GOOS {
+	switch goos {
 	case "darwin":
-		args := []string{
-			ia.loginCmdPath,
-			"-f", // already authenticated
-
-			// login typically discards the previous environment, but we want to
-			// preserve any environment variables that we currently have.
-			"-p",
-
-			"-h", ia.remoteIP, // -h is "remote host"
-			ia.localUser,
-		}
-		if !ia.hasTTY {
-			args[2] = "-pq" // -q is "quiet" which suppresses the login banner
-		}
-		if ia.cmdName != "" {
-			args = append(args, ia.cmdName)
-			args = append(args, ia.cmdArgs...)
+		if len(ia.loginFlags) > 0 && !loginFlagsPreserveEnv(ia.loginFlags) {
+			ia.loginEnv = darwinLoginEnv(environ)
 		}
-		return args
+		return ia.darwinLoginArgs()
 	case "linux":
 		if !ia.isShell || !ia.hasTTY {
 			// We can only use login command if a shell was requested with a TTY. If
@@ -788,6 +825,112 @@ func (ia *incubatorArgs) loginArgs() []string {
 	panic("unimplemented")
 }
 
+// darwinLoginArgs returns the arguments to use to exec the macOS login
+// binary.
+//
+// By default login is invoked as "login -f -p -h <remote-ip> <user>", with
+// -p becoming -pq if there is no TTY. If ia.loginFlags is set (from
+// TS_SSH_LOGIN_FLAGS), it replaces the -f and -p flags; it is used
+// verbatim, so it must include -f and -p if those are still wanted.
+// "-h <remote-ip>" is always appended after it, and so is -q if there is
+// no TTY, so the override only needs to cover what is the same for every
+// session.
+//
+// If ia.loginFlags doesn't include -p, login discards the environment, so
+// the command is run under env(1) to set the variables in ia.loginEnv in
+// the session. That puts their values, including the client-supplied TERM,
+// LANG and LC_* and the SSH_AUTH_SOCK path, on the command line where
+// other local users can see them, which is why it is not done when -p
+// already preserves them. env(1) would take a command name containing "="
+// as another assignment, so in that case it returns nil and the incubator
+// runs the command itself.
+func (ia *incubatorArgs) darwinLoginArgs() []string {
+	args := []string{ia.loginCmdPath}
+	preservesEnv := true
+	if len(ia.loginFlags) > 0 {
+		args = append(args, ia.loginFlags...)
+		if !ia.hasTTY {
+			args = append(args, "-q") // -q is "quiet" which suppresses the login banner
+		}
+		preservesEnv = loginFlagsPreserveEnv(ia.loginFlags)
+	} else {
+		// login typically discards the previous environment, but we want to
+		// preserve any environment variables that we currently have.
+		preserveEnv := "-p"
+		if !ia.hasTTY {
+			preserveEnv = "-pq" // -q is "quiet" which suppresses the login banner
+		}
+		args = append(args,
+			"-f", // already authenticated
+			preserveEnv,
+		)
+	}
+	args = append(args,
+		"-h", ia.remoteIP, // -h is "remote host"
+		ia.localUser,
+	)
+	if ia.cmdName != "" {
+		if !preservesEnv && len(ia.loginEnv) > 0 {
+			if strings.Contains(ia.cmdName, "=") {
+				return nil
+			}
+			args = append(args, "/usr/bin/env")
+			args = append(args, ia.loginEnv...)
+		}
+		args = append(args, ia.cmdName)
+		args = append(args, ia.cmdArgs...)
+	}
+	return args
+}
+
+// loginFlagsPreserveEnv reports whether flags, as passed to the macOS login
+// binary, include -p (possibly combined with other single-letter flags, as
+// in -fp or -pq). Like getopt(3), it stops at the first non-flag argument
+// or "--", and skips the argument to -h.
+func loginFlagsPreserveEnv(flags []string) bool {
+	for i := 0; i < len(flags); i++ {
+		f := flags[i]
+		if f == "--" || len(f) < 2 || f[0] != '-' {
+			return false
+		}
+		for j, c := range f[1:] {
+			if c == 'p' {
+				return true
+			}
+			if c == 'h' {
+				// The rest of f is the host; if there is none, it's the
+				// next argument.
+				if j == len(f)-2 {
+					i++
+				}
+				break
+			}
+		}
+	}
+	return false
+}
+
+// darwinLoginEnv returns the subset of environ that is explicitly exported
+// into the session started by the macOS login binary when its flags don't
+// preserve the environment. Those are the variables set by Tailscale SSH
+// for the session (SSH_CLIENT, SSH_CONNECTION, SSH_TTY and SSH_AUTH_SOCK)
+// and the ones accepted from the client (TERM, LANG and LC_*).
+func darwinLoginEnv(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		k, _, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case k == "SSH_CLIENT", k == "SSH_CONNECTION", k == "SSH_TTY", k == "SSH_AUTH_SOCK",
+			acceptEnvPair(kv):
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
 func setGroups(groupIDs []int) error {
 	if runtime.GOOS == "darwin" && len(groupIDs) > 16 {
 		// darwin returns "invalid argument" if more than 16 groups are passed to syscall.Setgroups
@@ -0,0 +1,213 @@
+// Copyright (c) Tailscale Inc & AUTHORS
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build linux || (darwin && !ios) || freebsd || openbsd
+
+package tailssh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"tailscale.com/envknob"
+)
+
+func TestDarwinLoginArgs(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin:/bin",
+		"SSH_TTY=/dev/ttys001",
+		"LANG=en_US.UTF-8",
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		environ []string
+		want    []string
+	}{
+		{
+			name: "default-tty",
+			args: []string{"--login-cmd=/usr/bin/login", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/zsh"},
+			want: []string{"/usr/bin/login", "-f", "-p", "-h", "100.64.0.1", "alice", "/bin/zsh"},
+		},
+		{
+			name: "default-no-tty",
+			args: []string{"--login-cmd=/usr/bin/login", "--local-user=alice", "--remote-ip=100.64.0.1", "--cmd=/bin/zsh", "--", "-c", "true"},
+			want: []string{"/usr/bin/login", "-f", "-pq", "-h", "100.64.0.1", "alice", "/bin/zsh", "-c", "true"},
+		},
+		{
+			name: "override",
+			args: []string{"--login-cmd=/opt/bin/login", "--login-flags=-fpq", "--local-user=alice", "--remote-ip=100.64.0.1", "--cmd=/bin/zsh"},
+			want: []string{"/opt/bin/login", "-fpq", "-q", "-h", "100.64.0.1", "alice", "/bin/zsh"},
+		},
+		{
+			name: "override-multiple",
+			args: []string{"--login-cmd=/usr/bin/login", "--login-flags= -f  -l ", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/zsh"},
+			want: []string{"/usr/bin/login", "-f", "-l", "-h", "100.64.0.1", "alice", "/bin/zsh"},
+		},
+		{
+			name:    "env-default-preserved",
+			args:    []string{"--login-cmd=/usr/bin/login", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/zsh", "--", "-l"},
+			environ: environ,
+			want:    []string{"/usr/bin/login", "-f", "-p", "-h", "100.64.0.1", "alice", "/bin/zsh", "-l"},
+		},
+		{
+			name:    "env-override-preserved",
+			args:    []string{"--login-cmd=/usr/bin/login", "--login-flags=-fp", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/zsh"},
+			environ: environ,
+			want:    []string{"/usr/bin/login", "-fp", "-h", "100.64.0.1", "alice", "/bin/zsh"},
+		},
+		{
+			name:    "env-override-not-preserved",
+			args:    []string{"--login-cmd=/usr/bin/login", "--login-flags=-f", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/zsh", "--", "-l"},
+			environ: environ,
+			want:    []string{"/usr/bin/login", "-f", "-h", "100.64.0.1", "alice", "/usr/bin/env", "SSH_TTY=/dev/ttys001", "LANG=en_US.UTF-8", "/bin/zsh", "-l"},
+		},
+		{
+			name:    "env-override-not-preserved-cmd-with-equals",
+			args:    []string{"--login-cmd=/usr/bin/login", "--login-flags=-f", "--local-user=alice", "--remote-ip=100.64.0.1", "--has-tty=true", "--cmd=/bin/FOO=bar"},
+			environ: environ,
+			want:    nil,
+		},
+		{
+			name: "no-env-override-not-preserved",
+			args: []string{"--login-cmd=/usr/bin/login", "--login-flags=-f", "--local-user=alice", "--remote-ip=100.64.0.1", "--cmd=/bin/zsh", "--", "-c", "true"},
+			want: []string{"/usr/bin/login", "-f", "-q", "-h", "100.64.0.1", "alice", "/bin/zsh", "-c", "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ia := parseIncubatorArgs(tt.args)
+			if got := ia.loginArgs("darwin", tt.environ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+			if tt.want != nil && !slices.Contains(tt.want, "/usr/bin/env") && ia.loginEnv != nil {
+				t.Errorf("loginEnv = %q; want nil", ia.loginEnv)
+			}
+		})
+	}
+}
+
+func TestLoginFlagsPreserveEnv(t *testing.T) {
+	tests := []struct {
+		flags []string
+		want  bool
+	}{
+		{[]string{"-p"}, true},
+		{[]string{"-f", "-p"}, true},
+		{[]string{"-fpq"}, true},
+		{[]string{"-f"}, false},
+		{[]string{"-f", "-q"}, false},
+		{[]string{"-h", "host"}, false},
+		{[]string{"-h", "-p"}, false},
+		{[]string{"-h", "host", "-p"}, true},
+		{[]string{"-fh", "-p"}, false},
+		{[]string{"-fh", "host", "-p"}, true},
+		{[]string{"-fhp"}, false},
+		{[]string{"-f", "--", "-p"}, false},
+		{[]string{"-f", "user", "-p"}, false},
+	}
+	for _, tt := range tests {
+		if got := loginFlagsPreserveEnv(tt.flags); got != tt.want {
+			t.Errorf("loginFlagsPreserveEnv(%q) = %v; want %v", tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestDarwinLoginEnv(t *testing.T) {
+	environ := []string{
+		"SHELL=/bin/zsh",
+		"USER=alice",
+		"HOME=/Users/alice",
+		"PATH=/usr/bin:/bin",
+		"TERM=xterm-256color",
+		"LANG=en_US.UTF-8",
+		"LC_ALL=en_US.UTF-8",
+		"SSH_CLIENT=100.64.0.2 51234 22",
+		"SSH_CONNECTION=100.64.0.2 51234 100.64.0.1 22",
+		"SSH_AUTH_SOCK=/tmp/tailscale-ssh-agent",
+		"SSH_TTY=/dev/ttys001",
+		"SSH_ORIGINAL_COMMAND=true",
+		"FOO=bar",
+		"malformed",
+	}
+	want := []string{
+		"TERM=xterm-256color",
+		"LANG=en_US.UTF-8",
+		"LC_ALL=en_US.UTF-8",
+		"SSH_CLIENT=100.64.0.2 51234 22",
+		"SSH_CONNECTION=100.64.0.2 51234 100.64.0.1 22",
+		"SSH_AUTH_SOCK=/tmp/tailscale-ssh-agent",
+		"SSH_TTY=/dev/ttys001",
+	}
+	if got := darwinLoginEnv(environ); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestLoginCmdArgs(t *testing.T) {
+	customLogin := filepath.Join(t.TempDir(), "login")
+	if err := os.WriteFile(customLogin, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var defaultArgs []string
+	if lp, err := exec.LookPath("login"); err == nil {
+		defaultArgs = []string{"--login-cmd=" + lp}
+	}
+
+	tests := []struct {
+		name  string
+		goos  string
+		cmd   string
+		flags string
+		want  []string
+	}{
+		{
+			name: "default",
+			goos: "darwin",
+			want: defaultArgs,
+		},
+		{
+			name:  "override",
+			goos:  "darwin",
+			cmd:   customLogin,
+			flags: "-f -l",
+			want:  []string{"--login-cmd=" + customLogin, "--login-flags=-f -l"},
+		},
+		{
+			name: "override-relative",
+			goos: "darwin",
+			cmd:  "login",
+			want: defaultArgs,
+		},
+		{
+			name: "override-missing",
+			goos: "darwin",
+			cmd:  filepath.Join(t.TempDir(), "does-not-exist"),
+			want: defaultArgs,
+		},
+		{
+			name:  "override-ignored-on-linux",
+			goos:  "linux",
+			cmd:   customLogin,
+			flags: "-f -l",
+			want:  defaultArgs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envknob.Setenv("TS_SSH_LOGIN_CMD", tt.cmd)
+			envknob.Setenv("TS_SSH_LOGIN_FLAGS", tt.flags)
+			t.Cleanup(func() {
+				envknob.Setenv("TS_SSH_LOGIN_CMD", "")
+				envknob.Setenv("TS_SSH_LOGIN_FLAGS", "")
+			})
+			if got := loginCmdArgs(t.Logf, tt.goos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -53,6 +53,13 @@
 	sshDisableSFTP       = envknob.RegisterBool("TS_SSH_DISABLE_SFTP")
 	sshDisableForwarding = envknob.RegisterBool("TS_SSH_DISABLE_FORWARDING")
 	sshDisablePTY        = envknob.RegisterBool("TS_SSH_DISABLE_PTY")
+
+	// sshLoginCmd and sshLoginFlags override the path to and flags passed
+	// to the login binary on macOS. The flags are split on whitespace and
+	// replace the default -f -p; -h and, for sessions without a TTY, -q are
+	// still added. See incubatorArgs.darwinLoginArgs.
+	sshLoginCmd   = envknob.RegisterString("TS_SSH_LOGIN_CMD")
+	sshLoginFlags = envknob.RegisterString("TS_SSH_LOGIN_FLAGS")
 )
 
 const (